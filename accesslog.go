@@ -0,0 +1,141 @@
+package bivalve
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AccessLogOptions configures RequestLogHandlerWithOptions.
+type AccessLogOptions struct {
+	// Format selects the access-log line shape: "common" (CLF),
+	// "combined" (adds Referer and User-Agent), or "json". An empty Format
+	// uses LogConfig.AccessLogFormat, and "common" if that is empty too.
+	Format string
+	// Logger receives the finished access-log Record; defaults to the
+	// package's default Logger.
+	Logger *Logger
+	// SkipPaths are request paths (exact match against r.URL.Path) that
+	// are not logged -- typically health checks.
+	SkipPaths []string
+	// RequestID extracts a request ID from the request, if any. Included
+	// in the "json" format's fields; ignored otherwise.
+	RequestID func(*http.Request) string
+}
+
+type webLoggingHandler struct {
+	handler http.Handler
+	opts    AccessLogOptions
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	length int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = 200
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.length += n
+	return n, err
+}
+
+// RequestLogHandler wraps h with an access-log handler using the
+// package's configured AccessLogFormat (see LogConfig.AccessLogFormat)
+// and default Logger.
+func RequestLogHandler(h http.Handler) http.Handler {
+	return RequestLogHandlerWithOptions(h, AccessLogOptions{})
+}
+
+// RequestLogHandlerWithOptions wraps h with an access-log handler
+// configured by opts. A zero AccessLogOptions behaves like
+// RequestLogHandler.
+func RequestLogHandlerWithOptions(h http.Handler, opts AccessLogOptions) http.Handler {
+	return webLoggingHandler{handler: h, opts: opts}
+}
+
+func (h webLoggingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, p := range h.opts.SkipPaths {
+		if r.URL.Path == p {
+			h.handler.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	start := time.Now().UTC()
+	sw := statusWriter{ResponseWriter: w}
+
+	h.handler.ServeHTTP(&sw, r)
+
+	elapsed := time.Since(start)
+
+	logger := h.opts.Logger
+	if logger == nil {
+		logger = defaultLogger
+	}
+
+	format := h.opts.Format
+	if format == "" {
+		format = accessLogFormat
+	}
+	if format == "" {
+		format = "common"
+	}
+
+	var requestID string
+	if h.opts.RequestID != nil {
+		requestID = h.opts.RequestID(r)
+	}
+
+	requestLine := fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, r.Proto)
+	ip := remoteIP(r)
+
+	switch format {
+	case "json":
+		logger.Infow("access",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", sw.status,
+			"bytes", sw.length,
+			"latency_ms", elapsed.Seconds()*1000,
+			"remote_ip", ip,
+			"user_agent", r.UserAgent(),
+			"referer", r.Referer(),
+			"request_id", requestID,
+		)
+	case "combined":
+		logger.Infof("%s - - [%s] %q %d %d %q %q",
+			ip, start.Format(clfTimeLayout), requestLine, sw.status, sw.length, r.Referer(), r.UserAgent())
+	default:
+		logger.Infof("%s - - [%s] %q %d %d",
+			ip, start.Format(clfTimeLayout), requestLine, sw.status, sw.length)
+	}
+}
+
+// clfTimeLayout is the timestamp format used by the Common/Combined Log
+// Format, e.g. "10/Oct/2023:13:55:36 -0700".
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// remoteIP prefers X-Forwarded-For (first entry) and X-Real-IP over
+// r.RemoteAddr, for requests passing through a proxy or load balancer.
+func remoteIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		if idx := strings.Index(xff, ","); idx >= 0 {
+			return strings.TrimSpace(xff[:idx])
+		}
+		return strings.TrimSpace(xff)
+	}
+	if xr := r.Header.Get("X-Real-IP"); xr != "" {
+		return xr
+	}
+	return r.RemoteAddr
+}
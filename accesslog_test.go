@@ -0,0 +1,114 @@
+package bivalve
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRemoteIPPrefersForwardedHeaders(t *testing.T) {
+	cases := []struct {
+		name    string
+		headers map[string]string
+		remote  string
+		want    string
+	}{
+		{"no headers falls back to RemoteAddr", nil, "10.0.0.1:1234", "10.0.0.1:1234"},
+		{"X-Real-IP used", map[string]string{"X-Real-IP": "1.2.3.4"}, "10.0.0.1:1234", "1.2.3.4"},
+		{"X-Forwarded-For single", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "10.0.0.1:1234", "1.2.3.4"},
+		{"X-Forwarded-For takes first of many", map[string]string{"X-Forwarded-For": "1.2.3.4, 5.6.7.8"}, "10.0.0.1:1234", "1.2.3.4"},
+		{"X-Forwarded-For wins over X-Real-IP", map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Real-IP": "5.6.7.8"}, "10.0.0.1:1234", "1.2.3.4"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = c.remote
+			for k, v := range c.headers {
+				r.Header.Set(k, v)
+			}
+			if got := remoteIP(r); got != c.want {
+				t.Errorf("remoteIP() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRequestLogHandlerSkipsConfiguredPaths(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+	level.Store(infoLevel)
+
+	rh := &recordingHandler{}
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := RequestLogHandlerWithOptions(inner, AccessLogOptions{
+		Logger:    NewLogger(rh),
+		SkipPaths: []string{"/healthz"},
+	})
+
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(rh.records) != 0 {
+		t.Errorf("expected skipped path to produce no access-log record, got %+v", rh.records)
+	}
+}
+
+func TestRequestLogHandlerJSONFormat(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+	level.Store(infoLevel)
+
+	rh := &recordingHandler{}
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("ok"))
+	})
+	h := RequestLogHandlerWithOptions(inner, AccessLogOptions{
+		Format: "json",
+		Logger: NewLogger(rh),
+		RequestID: func(r *http.Request) string {
+			return "req-1"
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/widgets", nil)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(rh.records) != 1 {
+		t.Fatalf("expected one access-log record, got %d", len(rh.records))
+	}
+	attrsByKey := map[string]interface{}{}
+	for _, a := range rh.records[0].Attrs {
+		attrsByKey[a.Key] = a.Value
+	}
+	if attrsByKey["status"] != http.StatusCreated {
+		t.Errorf("status = %v, want %d", attrsByKey["status"], http.StatusCreated)
+	}
+	if attrsByKey["path"] != "/widgets" {
+		t.Errorf("path = %v, want /widgets", attrsByKey["path"])
+	}
+	if attrsByKey["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, want req-1", attrsByKey["request_id"])
+	}
+}
+
+func TestRequestLogHandlerCommonFormat(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+	level.Store(infoLevel)
+
+	rh := &recordingHandler{}
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := RequestLogHandlerWithOptions(inner, AccessLogOptions{Logger: NewLogger(rh)})
+
+	req := httptest.NewRequest("GET", "/widgets", nil)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(rh.records) != 1 {
+		t.Fatalf("expected one access-log record, got %d", len(rh.records))
+	}
+	if !strings.Contains(rh.records[0].Message, "GET /widgets") {
+		t.Errorf("unexpected common-format message: %q", rh.records[0].Message)
+	}
+}
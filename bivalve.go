@@ -24,18 +24,21 @@ package bivalve
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
-	"net/http"
 	"os"
-	"runtime"
-	"strings"
-	"time"
+	"strconv"
+	"sync/atomic"
 )
 
 var (
-	valvelog       *log.Logger
-	level          int8
-	terminalOutput bool
+	// level is an atomic.Int32 rather than a plain int8 so SetLevel can
+	// be called concurrently with logging calls, e.g. from LevelHandler
+	// or the SIGUSR1 handler, without a lock.
+	level           atomic.Int32
+	terminalOutput  bool
+	defaultLogger   *Logger
+	accessLogFormat string
 )
 
 // LogConfig is the struct for passing in logging configuration that we care about.
@@ -47,6 +50,40 @@ type LogConfig struct {
 	// DisplayMinimal will if true display a minimal log output.  If false, a default log line prefix with date, file, line number will be displayed
 	DisplayMinimal bool `toml:"displayMinimal"`
 	TerminalOutput bool `toml:"displayMinimal"`
+	// Handlers, if set, are used verbatim instead of the handlers Configure
+	// would otherwise build from Output/TerminalOutput -- for callers that
+	// want e.g. Apache access lines going to a file and app logs to stderr
+	// at the same time. The ring buffer handler is still appended when
+	// RingBufferSize is set.
+	Handlers []Handler `toml:"-"`
+	// Format selects the wire format for built-in handlers: "text"
+	// (default, human-readable), "json", or "logfmt". Ignored when
+	// Handlers is set explicitly.
+	Format string `toml:"format"`
+	// VModule is a vmodule-style per-file verbosity spec, e.g.
+	// "bivalve/*=4,http/handlers.go=2". See SetVModule.
+	VModule string `toml:"vmodule"`
+
+	// RotateMaxSizeMB rotates the log file once it exceeds this size; 0
+	// disables size-based rotation.
+	RotateMaxSizeMB int `toml:"rotateMaxSizeMB"`
+	// RotateMaxAgeDays prunes rotated backups older than this many days;
+	// 0 disables age-based pruning.
+	RotateMaxAgeDays int `toml:"rotateMaxAgeDays"`
+	// RotateMaxBackups caps the number of rotated backups kept; 0
+	// disables count-based pruning.
+	RotateMaxBackups int `toml:"rotateMaxBackups"`
+	// RotateCompress gzips rotated backups.
+	RotateCompress bool `toml:"rotateCompress"`
+
+	// AccessLogFormat selects the line shape RequestLogHandler emits:
+	// "common" (CLF, default), "combined" (adds Referer/User-Agent), or
+	// "json". RequestLogHandlerWithOptions offers finer control.
+	AccessLogFormat string `toml:"accessLogFormat"`
+
+	// RingBufferSize, if > 0, keeps the last N Records in memory for
+	// DebugHandler to serve; 0 disables the ring buffer entirely.
+	RingBufferSize int `toml:"ringBufferSize"`
 }
 
 const (
@@ -54,6 +91,14 @@ const (
 	configLevelKey          = "BIVALVE_LEVEL"
 	configFilenameKey       = "BIVALVE_FILENAME"
 	configDisplayMinimalKey = "BIVALVE_DISPLAY_MINIMAL"
+	configVModuleKey        = "BIVALVE_VMODULE"
+
+	configRotateMaxSizeMBKey  = "BIVALVE_ROTATE_MAX_SIZE_MB"
+	configRotateMaxAgeDaysKey = "BIVALVE_ROTATE_MAX_AGE_DAYS"
+	configRotateMaxBackupsKey = "BIVALVE_ROTATE_MAX_BACKUPS"
+	configRotateCompressKey   = "BIVALVE_ROTATE_COMPRESS"
+	configAccessLogFormatKey  = "BIVALVE_ACCESS_LOG_FORMAT"
+	configRingBufferSizeKey   = "BIVALVE_RING_SIZE"
 
 	// ApacheFormatPattern is the default format used for apache access logs
 	ApacheFormatPattern = "%s - - [%s] \"%s %d %d\" %f\n"
@@ -65,30 +110,6 @@ const (
 	DebugColor = "\033[0;36m%s\033[0m"
 )
 
-type webLoggingHandler struct {
-	handler http.Handler
-}
-
-type statusWriter struct {
-	http.ResponseWriter
-	status int
-	length int
-}
-
-func (w *statusWriter) WriteHeader(status int) {
-	w.status = status
-	w.ResponseWriter.WriteHeader(status)
-}
-
-func (w *statusWriter) Write(b []byte) (int, error) {
-	if w.status == 0 {
-		w.status = 200
-	}
-	n, err := w.ResponseWriter.Write(b)
-	w.length += n
-	return n, err
-}
-
 func init() {
 	// pull configuration from config/log.config by default
 	conf := &LogConfig{}
@@ -96,147 +117,160 @@ func init() {
 	conf.Level = *flag.String(configLevelKey, getEnvConfigValueOr(configLevelKey, "info").(string), "log level; 'debug', 'info', 'error'")
 	conf.Filename = *flag.String(configFilenameKey, getEnvConfigValueOr(configFilenameKey, "bivalve.log").(string), "log filename")
 	conf.DisplayMinimal = *flag.Bool(configDisplayMinimalKey, getEnvConfigValueOr(configDisplayMinimalKey, false).(bool), "log filename")
+	conf.VModule = *flag.String(configVModuleKey, getEnvConfigValueOr(configVModuleKey, "").(string), "per-file verbosity, e.g. 'bivalve/*=4,http/handlers.go=2'")
+	conf.RotateMaxSizeMB = *flag.Int(configRotateMaxSizeMBKey, getEnvConfigIntValueOr(configRotateMaxSizeMBKey, 0), "rotate the log file once it exceeds this size in MB; 0 disables")
+	conf.RotateMaxAgeDays = *flag.Int(configRotateMaxAgeDaysKey, getEnvConfigIntValueOr(configRotateMaxAgeDaysKey, 0), "prune rotated log backups older than this many days; 0 disables")
+	conf.RotateMaxBackups = *flag.Int(configRotateMaxBackupsKey, getEnvConfigIntValueOr(configRotateMaxBackupsKey, 0), "keep at most this many rotated log backups; 0 disables")
+	conf.RotateCompress = *flag.Bool(configRotateCompressKey, getEnvConfigValueOr(configRotateCompressKey, false).(bool), "gzip rotated log backups")
+	conf.AccessLogFormat = *flag.String(configAccessLogFormatKey, getEnvConfigValueOr(configAccessLogFormatKey, "common").(string), "access log format; 'common', 'combined', or 'json'")
+	conf.RingBufferSize = *flag.Int(configRingBufferSizeKey, getEnvConfigIntValueOr(configRingBufferSizeKey, 0), "number of recent log records to retain for DebugHandler; 0 disables")
 
 	Configure(conf)
 }
 
 // Configure will set the logger instance configuration should an application want to explictly set the configuration
 func Configure(conf *LogConfig) {
-	writer := os.Stderr
-	switch conf.Output {
-	case "file":
-		f, err := os.OpenFile(conf.Filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			log.Println(err)
-		}
-		writer = f
-		defer f.Close()
-	case "stdout":
-		writer = os.Stdout
-
-	}
-
 	switch conf.Level {
 	case "debug":
-
-		level = debugLevel
+		level.Store(debugLevel)
 	case "error":
-
-		level = errorLevel
+		level.Store(errorLevel)
 	default:
-
-		level = infoLevel
+		level.Store(infoLevel)
 	}
 
-	if conf.TerminalOutput {
-		terminalOutput = true
-	} else {
-		terminalOutput = false
+	terminalOutput = conf.TerminalOutput
+	accessLogFormat = conf.AccessLogFormat
+
+	if err := SetVModule(conf.VModule); err != nil {
+		log.Println(err)
 	}
 
-	logflags := log.Ldate | log.Ltime | log.Lmicroseconds | log.LUTC | log.Lshortfile
-	if conf.DisplayMinimal {
-		logflags = 0
+	handlers := conf.Handlers
+	if len(handlers) == 0 {
+		handlers = buildHandlers(conf)
+	}
 
+	ringBuffer = nil
+	if conf.RingBufferSize > 0 {
+		rb := NewRingBufferHandler(conf.RingBufferSize)
+		handlers = append(handlers, rb)
+		ringBuffer = rb
 	}
-	valvelog = log.New(writer, "", logflags)
+
+	defaultLogger = NewLogger(handlers...)
+
 	Debugf("Log Config set to : %+v", conf)
 }
 
+// buildHandlers turns Output/TerminalOutput into the Handler slice
+// Configure uses when the caller hasn't supplied its own. "both" sends
+// every record to the configured file *and* stderr, rather than silently
+// dropping to stderr only.
+func buildHandlers(conf *LogConfig) []Handler {
+	switch conf.Output {
+	case "file":
+		return []Handler{fileHandler(conf)}
+	case "both":
+		return []Handler{fileHandler(conf), streamHandler(os.Stderr, conf)}
+	case "stdout":
+		return []Handler{streamHandler(os.Stdout, conf)}
+	default:
+		return []Handler{streamHandler(os.Stderr, conf)}
+	}
+}
+
+// streamHandler builds a handler for an interactive stream, where
+// TerminalOutput may request colorized text.
+func streamHandler(w io.Writer, conf *LogConfig) Handler {
+	return handlerFor(w, conf, conf.TerminalOutput)
+}
+
+// fileHandler builds a handler writing to conf.Filename through a
+// RotatingFileWriter configured from conf's Rotate* fields. Files never
+// get ANSI color codes regardless of TerminalOutput.
+func fileHandler(conf *LogConfig) Handler {
+	w := NewRotatingFileWriter(conf.Filename)
+	w.MaxSizeMB = conf.RotateMaxSizeMB
+	w.MaxAgeDays = conf.RotateMaxAgeDays
+	w.MaxBackups = conf.RotateMaxBackups
+	w.Compress = conf.RotateCompress
+	return handlerFor(w, conf, false)
+}
+
+// handlerFor picks a Handler implementation for w based on conf.Format,
+// falling back to plain or colorized text when Format is unset.
+func handlerFor(w io.Writer, conf *LogConfig, colorize bool) Handler {
+	switch conf.Format {
+	case "json":
+		return NewJSONHandler(w)
+	case "logfmt":
+		return NewLogfmtHandler(w)
+	default:
+		if colorize {
+			return NewTerminalHandler(w)
+		}
+		return NewTextHandler(w)
+	}
+}
+
+// currentLevel exposes the package's configured verbosity threshold to
+// the rest of the package (e.g. Record's enabled check).
+func currentLevel() Level {
+	return Level(level.Load())
+}
+
 // Info will log a string message
 func Info(s string) {
-	if level >= infoLevel {
-		valvelog.Output(2, s)
-	}
+	defaultLogger.emit(3, LevelInfo, s, nil)
 }
 
 // Infof will log a formatted string message
 func Infof(s string, args ...interface{}) {
+	defaultLogger.emit(3, LevelInfo, fmt.Sprintf(s, args...), nil)
+}
 
-	if level >= infoLevel {
-		valvelog.Output(2, fmt.Sprintf(s, args...))
-	}
+// Infow will log a message along with alternating key/value pairs,
+// e.g. Infow("request handled", "path", r.URL.Path, "status", 200).
+func Infow(msg string, keysAndValues ...interface{}) {
+	defaultLogger.emit(3, LevelInfo, msg, kvToAttrs(keysAndValues))
 }
 
 // Debug will log a string message
 func Debug(s string) {
-	if level >= debugLevel {
-		if terminalOutput {
-			s = fmt.Sprintf(DebugColor, s)
-		}
-		valvelog.Output(2, s)
-	}
+	defaultLogger.emit(3, LevelDebug, s, nil)
 }
 
 // Debugf will log a formatted string message
 func Debugf(s string, args ...interface{}) {
-	if level >= debugLevel {
-		msg := fmt.Sprintf(s, args...)
-		if terminalOutput {
-			msg = fmt.Sprintf(DebugColor, msg)
-		}
-		valvelog.Output(2, msg)
-	}
+	defaultLogger.emit(3, LevelDebug, fmt.Sprintf(s, args...), nil)
+}
+
+// Debugw will log a message along with alternating key/value pairs.
+func Debugw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.emit(3, LevelDebug, msg, kvToAttrs(keysAndValues))
 }
 
 // Error will log a string message
 func Error(s string) {
-	if terminalOutput {
-		s = fmt.Sprintf(ErrorColor, s)
-	}
-	valvelog.Output(2, s)
-
+	defaultLogger.emit(3, LevelError, s, nil)
 }
 
 // Errorf will log a formatted string message
 func Errorf(s string, args ...interface{}) {
-	msg := fmt.Sprintf(s, args...)
-	if terminalOutput {
-		msg = fmt.Sprintf(ErrorColor, msg)
-	}
-	valvelog.Output(2, msg)
+	defaultLogger.emit(3, LevelError, fmt.Sprintf(s, args...), nil)
 }
 
-// RequestLogHandler http request log handler
-func RequestLogHandler(h http.Handler) http.Handler {
-
-	return webLoggingHandler{handler: h}
+// Errorw will log a message along with alternating key/value pairs.
+func Errorw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.emit(3, LevelError, msg, kvToAttrs(keysAndValues))
 }
 
-// TODO add httpstatus, add response size
-func (h webLoggingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-
-	start := time.Now()
-	sw := statusWriter{ResponseWriter: w}
-
-	h.handler.ServeHTTP(&sw, r)
-
-	endingTime := time.Now().UTC()
-
-	type ApacheLogRecord struct {
-		ip                    string
-		time                  time.Time
-		method, uri, protocol string
-		status                int
-		responseBytes         int64
-		elapsedTime           time.Duration
-	}
-
-	record := &ApacheLogRecord{
-		ip:            r.RemoteAddr,
-		time:          time.Time{},
-		method:        r.Method,
-		uri:           r.RequestURI,
-		protocol:      r.Proto,
-		status:        sw.status,
-		elapsedTime:   endingTime.Sub(start),
-		responseBytes: int64(sw.length),
-	}
-	timeFormatted := record.time.Format("02/Jan/2006 03:04:05")
-	requestLine := fmt.Sprintf("%s %s %s", record.method, record.uri, record.protocol)
-	Infof(ApacheFormatPattern, record.ip, timeFormatted, requestLine, record.status, record.responseBytes,
-		record.elapsedTime.Seconds())
-
+// With returns a child Logger, based on the default Logger, that carries
+// attrs on every Record it emits -- useful for threading a request ID or
+// similar context through a call chain.
+func With(attrs ...Attr) *Logger {
+	return defaultLogger.With(attrs...)
 }
 
 // getConfigValue will check if an env variable is set.  If
@@ -250,16 +284,17 @@ func getEnvConfigValueOr(envKey string, defaultValue interface{}) interface{} {
 	return configValue
 }
 
-func header() string {
-	_, file, line, ok := runtime.Caller(2)
-	if !ok {
-		file = "???"
-		line = 1
-	} else {
-		slash := strings.LastIndex(file, "/")
-		if slash >= 0 {
-			file = file[slash+1:]
-		}
+// getEnvConfigIntValueOr is getEnvConfigValueOr for integer flag
+// defaults (MaxSizeMB, MaxAgeDays, ...); an unparseable env value falls
+// back to defaultValue rather than panicking on flag registration.
+func getEnvConfigIntValueOr(envKey string, defaultValue int) int {
+	env := os.Getenv(envKey)
+	if env == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil {
+		return defaultValue
 	}
-	return fmt.Sprintf("[%s:%d]", file, line)
+	return n
 }
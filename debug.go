@@ -0,0 +1,78 @@
+package bivalve
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// ringBuffer is the RingBufferHandler DebugHandler reads from, wired up
+// by Configure when LogConfig.RingBufferSize is set.
+var ringBuffer *RingBufferHandler
+
+// DebugHandler returns an http.Handler serving "/logs?tail=100" (the
+// last n retained Records, as a JSON array) and "/logs/stream" (the same
+// Records as they happen, as Server-Sent Events). It requires
+// LogConfig.RingBufferSize to be set; otherwise every request gets a 503.
+func DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/logs", serveLogsTail)
+	mux.HandleFunc("/logs/stream", serveLogsStream)
+	return mux
+}
+
+func serveLogsTail(w http.ResponseWriter, r *http.Request) {
+	rb := ringBuffer
+	if rb == nil {
+		http.Error(w, "bivalve: ring buffer not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	n := 100
+	if v := r.URL.Query().Get("tail"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			n = parsed
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rb.Snapshot(n))
+}
+
+func serveLogsStream(w http.ResponseWriter, r *http.Request) {
+	rb := ringBuffer
+	if rb == nil {
+		http.Error(w, "bivalve: ring buffer not configured", http.StatusServiceUnavailable)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "bivalve: streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch, cancel := rb.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	for {
+		select {
+		case rec, ok := <-ch:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(rec)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package bivalve
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeLogsTailWithoutRingBufferIs503(t *testing.T) {
+	orig := ringBuffer
+	ringBuffer = nil
+	defer func() { ringBuffer = orig }()
+
+	req := httptest.NewRequest("GET", "/logs", nil)
+	rec := httptest.NewRecorder()
+	DebugHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestServeLogsTailReturnsSnapshot(t *testing.T) {
+	orig := ringBuffer
+	ringBuffer = NewRingBufferHandler(10)
+	defer func() { ringBuffer = orig }()
+
+	ringBuffer.Handle(recordAt("hello", time.Now()))
+
+	req := httptest.NewRequest("GET", "/logs?tail=5", nil)
+	rec := httptest.NewRecorder()
+	DebugHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var got []struct {
+		Message string
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(got) != 1 || got[0].Message != "hello" {
+		t.Errorf("got %+v, want one record with message hello", got)
+	}
+}
+
+func TestServeLogsStreamWithoutRingBufferIs503(t *testing.T) {
+	orig := ringBuffer
+	ringBuffer = nil
+	defer func() { ringBuffer = orig }()
+
+	req := httptest.NewRequest("GET", "/logs/stream", nil)
+	rec := httptest.NewRecorder()
+	DebugHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestServeLogsStreamEmitsSSE(t *testing.T) {
+	orig := ringBuffer
+	ringBuffer = NewRingBufferHandler(10)
+	defer func() { ringBuffer = orig }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/logs/stream", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		DebugHandler().ServeHTTP(rec, req)
+		close(done)
+	}()
+
+	// Give serveLogsStream time to subscribe before publishing.
+	time.Sleep(50 * time.Millisecond)
+	ringBuffer.Handle(recordAt("streamed", time.Now()))
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "data: ") || !strings.Contains(body, "streamed") {
+		t.Errorf("expected SSE body to contain the streamed record, got %q", body)
+	}
+}
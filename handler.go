@@ -0,0 +1,188 @@
+package bivalve
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Handler consumes Records and writes them to some sink (terminal, file,
+// network, ...). Implementations must be safe for concurrent use, since a
+// Logger may fan the same Record out to several handlers at once.
+type Handler interface {
+	Handle(r Record) error
+}
+
+// HandlerFunc adapts a plain function to the Handler interface.
+type HandlerFunc func(r Record) error
+
+// Handle calls f(r).
+func (f HandlerFunc) Handle(r Record) error { return f(r) }
+
+// TerminalHandler writes colorized, human-readable lines to w. It's the
+// handler Configure wires up when TerminalOutput is set.
+type TerminalHandler struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewTerminalHandler returns a TerminalHandler writing to w.
+func NewTerminalHandler(w io.Writer) *TerminalHandler {
+	return &TerminalHandler{w: w}
+}
+
+// Handle writes r to the handler's writer, colorized by level.
+func (h *TerminalHandler) Handle(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	line := formatPlain(r)
+	switch r.Level {
+	case LevelError:
+		line = fmt.Sprintf(ErrorColor, line)
+	case LevelDebug:
+		line = fmt.Sprintf(DebugColor, line)
+	}
+	_, err := fmt.Fprintln(h.w, line)
+	return err
+}
+
+// TextHandler writes plain, uncolored lines to w.
+type TextHandler struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewTextHandler returns a TextHandler writing to w.
+func NewTextHandler(w io.Writer) *TextHandler {
+	return &TextHandler{w: w}
+}
+
+// Handle writes r to the handler's writer.
+func (h *TextHandler) Handle(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := fmt.Fprintln(h.w, formatPlain(r))
+	return err
+}
+
+func formatPlain(r Record) string {
+	ts := r.Time.Format("2006/01/02 15:04:05.000000")
+	line := fmt.Sprintf("%s %s %s", ts, r.Level, r.Message)
+	if r.File != "" {
+		line = fmt.Sprintf("%s [%s:%d] %s %s", ts, r.File, r.Line, r.Level, r.Message)
+	}
+	for _, a := range r.Attrs {
+		line += fmt.Sprintf(" %s=%s", a.Key, formatLogfmtValue(a.Value))
+	}
+	return line
+}
+
+// JSONHandler writes each Record as a single line of JSON to w.
+type JSONHandler struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewJSONHandler returns a JSONHandler writing to w.
+func NewJSONHandler(w io.Writer) *JSONHandler {
+	return &JSONHandler{w: w, enc: json.NewEncoder(w)}
+}
+
+// Handle writes r to the handler's writer as a JSON object.
+func (h *JSONHandler) Handle(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.enc.Encode(newJSONRecord(r))
+}
+
+// jsonRecord is the wire shape for a Record: fixed fields plus Attrs
+// flattened into a map so consumers don't need to know about Attr.
+type jsonRecord struct {
+	Time  string                 `json:"time"`
+	Level string                 `json:"level"`
+	Msg   string                 `json:"msg"`
+	File  string                 `json:"file,omitempty"`
+	Attrs map[string]interface{} `json:"attrs,omitempty"`
+}
+
+func newJSONRecord(r Record) jsonRecord {
+	jr := jsonRecord{
+		Time:  r.Time.UTC().Format("2006-01-02T15:04:05.000000000Z07:00"),
+		Level: r.Level.String(),
+		Msg:   r.Message,
+	}
+	if r.File != "" {
+		jr.File = fmt.Sprintf("%s:%d", r.File, r.Line)
+	}
+	if len(r.Attrs) > 0 {
+		jr.Attrs = make(map[string]interface{}, len(r.Attrs))
+		for _, a := range r.Attrs {
+			jr.Attrs[a.Key] = normalizeAttrValue(a.Value)
+		}
+	}
+	return jr
+}
+
+// LogfmtHandler writes each Record as a single logfmt line (k=v k2="v 2")
+// to w.
+type LogfmtHandler struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewLogfmtHandler returns a LogfmtHandler writing to w.
+func NewLogfmtHandler(w io.Writer) *LogfmtHandler {
+	return &LogfmtHandler{w: w}
+}
+
+// Handle writes r to the handler's writer in logfmt.
+func (h *LogfmtHandler) Handle(r Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "time=%s level=%s msg=%s",
+		r.Time.UTC().Format(time.RFC3339Nano), r.Level, formatLogfmtValue(r.Message))
+	if r.File != "" {
+		fmt.Fprintf(&b, " file=%s:%d", r.File, r.Line)
+	}
+	for _, a := range r.Attrs {
+		fmt.Fprintf(&b, " %s=%s", a.Key, formatLogfmtValue(a.Value))
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+// normalizeAttrValue renders values that don't have an obvious wire
+// representation -- errors and times -- as strings before they reach a
+// handler.
+func normalizeAttrValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	default:
+		return val
+	}
+}
+
+// formatLogfmtValue renders v as a logfmt value, quoting strings that
+// contain spaces, quotes, or an '='.
+func formatLogfmtValue(v interface{}) string {
+	v = normalizeAttrValue(v)
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	if strings.ContainsAny(s, " \"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package bivalve
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRecord() Record {
+	return Record{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   LevelInfo,
+		Message: "hello",
+		File:    "foo.go",
+		Line:    42,
+	}
+}
+
+func TestTextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewTextHandler(&buf)
+	if err := h.Handle(testRecord()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "hello") || !strings.Contains(out, "foo.go:42") {
+		t.Errorf("unexpected text output: %q", out)
+	}
+}
+
+func TestTextHandlerIncludesAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewTextHandler(&buf)
+	r := testRecord()
+	r.Attrs = []Attr{{Key: "path", Value: "/healthz"}}
+	if err := h.Handle(r); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "path=/healthz") {
+		t.Errorf("expected attrs in text output, got %q", buf.String())
+	}
+}
+
+func TestJSONHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewJSONHandler(&buf)
+	if err := h.Handle(testRecord()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{`"level":"INFO"`, `"msg":"hello"`, `"file":"foo.go:42"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("JSON output %q missing %q", out, want)
+		}
+	}
+}
+
+func TestTerminalHandlerColorizesErrors(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewTerminalHandler(&buf)
+	r := testRecord()
+	r.Level = LevelError
+	if err := h.Handle(r); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\033[1;31m") {
+		t.Errorf("expected error color escape in output: %q", buf.String())
+	}
+}
@@ -0,0 +1,68 @@
+package bivalve
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoggerInfowAttachesAttrs(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+	level.Store(infoLevel)
+
+	h := &recordingHandler{}
+	l := NewLogger(h)
+	l.Infow("request handled", "path", "/healthz", "status", 200)
+
+	if len(h.records) != 1 {
+		t.Fatalf("expected one record, got %d", len(h.records))
+	}
+	attrs := h.records[0].Attrs
+	if len(attrs) != 2 || attrs[0].Key != "path" || attrs[1].Key != "status" {
+		t.Fatalf("unexpected attrs: %+v", attrs)
+	}
+}
+
+func TestFormatLogfmtValueQuoting(t *testing.T) {
+	cases := []struct {
+		in   interface{}
+		want string
+	}{
+		{"plain", "plain"},
+		{"has space", `"has space"`},
+		{`has"quote`, `"has\"quote"`},
+		{42, "42"},
+		{errors.New("boom"), "boom"},
+	}
+	for _, c := range cases {
+		if got := formatLogfmtValue(c.in); got != c.want {
+			t.Errorf("formatLogfmtValue(%#v) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeAttrValueTime(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	got := normalizeAttrValue(ts)
+	want := ts.Format(time.RFC3339Nano)
+	if got != want {
+		t.Errorf("normalizeAttrValue(time) = %v, want %v", got, want)
+	}
+}
+
+func TestLogfmtHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewLogfmtHandler(&buf)
+	r := testRecord()
+	r.Attrs = []Attr{{Key: "path", Value: "has space"}}
+	if err := h.Handle(r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "msg=hello") || !strings.Contains(out, `path="has space"`) {
+		t.Errorf("unexpected logfmt output: %q", out)
+	}
+}
@@ -0,0 +1,81 @@
+package bivalve
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// SetLevel atomically swaps the package's verbosity threshold. Valid
+// values are "debug", "info", and "error"; anything else is rejected
+// rather than silently falling back to "info" the way Configure does,
+// since callers of SetLevel are making an explicit runtime change.
+func SetLevel(s string) error {
+	var lvl int32
+	switch s {
+	case "debug":
+		lvl = debugLevel
+	case "info":
+		lvl = infoLevel
+	case "error":
+		lvl = errorLevel
+	default:
+		return fmt.Errorf("bivalve: unknown level %q", s)
+	}
+	level.Store(lvl)
+	// V(n) memoizes its decision per call site; a level change must
+	// invalidate it the same way SetVModule already does, or call sites
+	// that already fired once never notice the new level.
+	resetVCache()
+	return nil
+}
+
+// GetLevel returns the package's current verbosity threshold as a
+// string: "debug", "info", or "error".
+func GetLevel() string {
+	switch int8(level.Load()) {
+	case debugLevel:
+		return "debug"
+	case errorLevel:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler returns an http.Handler for runtime level control: GET
+// returns the current level as JSON ({"level":"info"}); PUT or POST with
+// the same shape atomically changes it. This is the dynamic-level
+// pattern common in production Go services (slog's LevelVar, capnslog,
+// zap's AtomicLevel), so an incident can be debugged without a restart.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeLevel(w, GetLevel())
+		case http.MethodPut, http.MethodPost:
+			var body levelBody
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, "bivalve: invalid request body", http.StatusBadRequest)
+				return
+			}
+			if err := SetLevel(body.Level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeLevel(w, GetLevel())
+		default:
+			w.Header().Set("Allow", "GET, POST, PUT")
+			http.Error(w, "bivalve: method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeLevel(w http.ResponseWriter, lvl string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(levelBody{Level: lvl})
+}
@@ -0,0 +1,124 @@
+package bivalve
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetLevelAndGetLevel(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetLevel(); got != "debug" {
+		t.Errorf("GetLevel() = %q, want debug", got)
+	}
+}
+
+func TestSetLevelRejectsUnknown(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("expected an error for an unknown level name")
+	}
+}
+
+func TestLevelHandlerGet(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+	SetLevel("info")
+
+	req := httptest.NewRequest(http.MethodGet, "/level", nil)
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, req)
+
+	var body levelBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Level != "info" {
+		t.Errorf("GET /level = %q, want info", body.Level)
+	}
+}
+
+func TestLevelHandlerPut(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+
+	req := httptest.NewRequest(http.MethodPut, "/level", bytes.NewBufferString(`{"level":"debug"}`))
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if GetLevel() != "debug" {
+		t.Errorf("GetLevel() = %q, want debug after PUT", GetLevel())
+	}
+}
+
+func TestLevelHandlerPutRejectsInvalidLevel(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+
+	req := httptest.NewRequest(http.MethodPut, "/level", bytes.NewBufferString(`{"level":"verbose"}`))
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestLevelHandlerRejectsOtherMethods(t *testing.T) {
+	req := httptest.NewRequest(http.MethodDelete, "/level", nil)
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", rec.Code)
+	}
+	if allow := rec.Header().Get("Allow"); !strings.Contains(allow, "GET") {
+		t.Errorf("Allow header = %q, want it to list GET", allow)
+	}
+}
+
+// TestSetLevelConcurrentWithVDepth reproduces the vcache race/panic
+// flagged in review: SetLevel resets the V(n) memoization cache on every
+// call, and that reset must not race with VDepth's concurrent
+// Load/Store of the same cache. Run with -race.
+func TestSetLevelConcurrentWithVDepth(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				V(1)
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		SetLevel("debug")
+		SetLevel("info")
+	}
+	close(stop)
+	wg.Wait()
+}
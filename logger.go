@@ -0,0 +1,115 @@
+package bivalve
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Logger emits structured Records to one or more Handlers. The
+// package-level Info/Debug/Error functions are thin wrappers around a
+// default Logger so existing callers don't need to change.
+type Logger struct {
+	handlers []Handler
+	attrs    []Attr
+}
+
+// NewLogger returns a Logger that fans every Record out to each of
+// handlers.
+func NewLogger(handlers ...Handler) *Logger {
+	return &Logger{handlers: handlers}
+}
+
+// With returns a child Logger that carries attrs on every Record it
+// emits, in addition to the receiver's own attrs. It's the mechanism for
+// threading things like a request ID through a call chain without
+// passing it to every log call explicitly.
+func (l *Logger) With(attrs ...Attr) *Logger {
+	combined := make([]Attr, 0, len(l.attrs)+len(attrs))
+	combined = append(combined, l.attrs...)
+	combined = append(combined, attrs...)
+	return &Logger{handlers: l.handlers, attrs: combined}
+}
+
+// Info logs a string message at info level.
+func (l *Logger) Info(s string) { l.emit(3, LevelInfo, s, nil) }
+
+// Infof logs a formatted message at info level.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.emit(3, LevelInfo, fmt.Sprintf(format, args...), nil)
+}
+
+// Infow logs msg at info level along with alternating key/value pairs,
+// e.g. Infow("request handled", "path", r.URL.Path, "status", 200).
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.emit(3, LevelInfo, msg, kvToAttrs(keysAndValues))
+}
+
+// Debug logs a string message at debug level.
+func (l *Logger) Debug(s string) { l.emit(3, LevelDebug, s, nil) }
+
+// Debugf logs a formatted message at debug level.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.emit(3, LevelDebug, fmt.Sprintf(format, args...), nil)
+}
+
+// Debugw logs msg at debug level along with alternating key/value pairs.
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.emit(3, LevelDebug, msg, kvToAttrs(keysAndValues))
+}
+
+// Error logs a string message at error level. Error records are always
+// emitted regardless of the configured verbosity.
+func (l *Logger) Error(s string) { l.emit(3, LevelError, s, nil) }
+
+// Errorf logs a formatted message at error level.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.emit(3, LevelError, fmt.Sprintf(format, args...), nil)
+}
+
+// Errorw logs msg at error level along with alternating key/value pairs.
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.emit(3, LevelError, msg, kvToAttrs(keysAndValues))
+}
+
+// emit builds a Record for msg at lvl, attributing it to the source line
+// calldepth frames up, and hands it to every handler if lvl is enabled
+// under the current verbosity threshold. extraAttrs, if any, are
+// appended after the Logger's own With attrs for this Record only.
+func (l *Logger) emit(calldepth int, lvl Level, msg string, extraAttrs []Attr) {
+	if !enabled(lvl) {
+		return
+	}
+	attrs := l.attrs
+	if len(extraAttrs) > 0 {
+		attrs = make([]Attr, 0, len(l.attrs)+len(extraAttrs))
+		attrs = append(attrs, l.attrs...)
+		attrs = append(attrs, extraAttrs...)
+	}
+	file, line := callsite(calldepth)
+	r := Record{
+		Time:    time.Now(),
+		Level:   lvl,
+		Message: msg,
+		Attrs:   attrs,
+		File:    file,
+		Line:    line,
+	}
+	for _, h := range l.handlers {
+		h.Handle(r)
+	}
+}
+
+// callsite returns the trimmed file name and line number skip frames up
+// from the runtime.Caller call itself.
+func callsite(skip int) (file string, line int) {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "???", 0
+	}
+	if idx := strings.LastIndex(file, "/"); idx >= 0 {
+		file = file[idx+1:]
+	}
+	return file, line
+}
@@ -0,0 +1,59 @@
+package bivalve
+
+import "testing"
+
+type recordingHandler struct {
+	records []Record
+}
+
+func (h *recordingHandler) Handle(r Record) error {
+	h.records = append(h.records, r)
+	return nil
+}
+
+func TestLoggerInfoRespectsLevel(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+	level.Store(infoLevel)
+
+	h := &recordingHandler{}
+	l := NewLogger(h)
+	l.Debug("should be dropped")
+	l.Info("should appear")
+
+	if len(h.records) != 1 || h.records[0].Message != "should appear" {
+		t.Fatalf("got records %+v, want exactly one info record", h.records)
+	}
+}
+
+func TestLoggerErrorAlwaysEmitted(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+	level.Store(errorLevel)
+
+	h := &recordingHandler{}
+	l := NewLogger(h)
+	l.Error("boom")
+
+	if len(h.records) != 1 {
+		t.Fatalf("expected error to be emitted regardless of level, got %+v", h.records)
+	}
+}
+
+func TestLoggerWithCarriesAttrs(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+	level.Store(infoLevel)
+
+	h := &recordingHandler{}
+	l := NewLogger(h).With(Attr{Key: "request_id", Value: "abc"})
+	l.Info("handled")
+
+	if len(h.records) != 1 {
+		t.Fatalf("expected one record, got %d", len(h.records))
+	}
+	attrs := h.records[0].Attrs
+	if len(attrs) != 1 || attrs[0].Key != "request_id" || attrs[0].Value != "abc" {
+		t.Fatalf("unexpected attrs: %+v", attrs)
+	}
+}
@@ -0,0 +1,79 @@
+package bivalve
+
+import (
+	"strconv"
+	"time"
+)
+
+// Level is the severity of a log Record. Unlike the usual "higher is
+// worse" convention, Level values here mirror the historical vvvvv
+// verbosity knob: the configured level must be >= a call's Level for
+// that call to be emitted (Error is always emitted regardless).
+type Level int8
+
+const (
+	LevelError Level = errorLevel
+	LevelInfo  Level = infoLevel
+	LevelDebug Level = debugLevel
+)
+
+// String renders the level the way it appears in log output.
+func (l Level) String() string {
+	switch l {
+	case LevelError:
+		return "ERROR"
+	case LevelInfo:
+		return "INFO"
+	case LevelDebug:
+		return "DEBUG"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarshalJSON renders the level as its string form (e.g. "INFO") rather
+// than the underlying int8, so JSON consumers like DebugHandler don't
+// need to know the numeric convention.
+func (l Level) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(l.String())), nil
+}
+
+// Attr is a single structured key/value pair attached to a Record.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// Record is a single structured log entry passed to a Handler. It is the
+// common currency between Logger and whatever sinks are configured --
+// terminal, file, JSON, etc.
+type Record struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Attrs   []Attr
+	File    string
+	Line    int
+}
+
+// kvToAttrs turns an alternating key/value slice (as accepted by Infow,
+// Debugw, Errorw, ...) into Attrs. A trailing key with no value is
+// dropped rather than panicking.
+func kvToAttrs(keysAndValues []interface{}) []Attr {
+	attrs := make([]Attr, 0, len(keysAndValues)/2)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		key, _ := keysAndValues[i].(string)
+		attrs = append(attrs, Attr{Key: key, Value: keysAndValues[i+1]})
+	}
+	return attrs
+}
+
+// enabled reports whether a Record at lvl should be emitted given the
+// package's current verbosity threshold. Error records are always
+// emitted, matching the historical behavior of Error/Errorf.
+func enabled(lvl Level) bool {
+	if lvl == LevelError {
+		return true
+	}
+	return int8(currentLevel()) >= int8(lvl)
+}
@@ -0,0 +1,56 @@
+package bivalve
+
+import "testing"
+
+func TestLevelString(t *testing.T) {
+	cases := map[Level]string{
+		LevelError: "ERROR",
+		LevelInfo:  "INFO",
+		LevelDebug: "DEBUG",
+		Level(0):   "UNKNOWN",
+	}
+	for lvl, want := range cases {
+		if got := lvl.String(); got != want {
+			t.Errorf("Level(%d).String() = %q, want %q", int8(lvl), got, want)
+		}
+	}
+}
+
+func TestLevelMarshalJSON(t *testing.T) {
+	b, err := LevelInfo.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `"INFO"` {
+		t.Errorf("MarshalJSON = %s, want %q", b, `"INFO"`)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+	level.Store(infoLevel)
+
+	if !enabled(LevelInfo) {
+		t.Error("LevelInfo should be enabled at info level")
+	}
+	if enabled(LevelDebug) {
+		t.Error("LevelDebug should not be enabled at info level")
+	}
+	if !enabled(LevelError) {
+		t.Error("LevelError must always be enabled regardless of the configured level")
+	}
+}
+
+func TestKVToAttrs(t *testing.T) {
+	attrs := kvToAttrs([]interface{}{"path", "/healthz", "status", 200, "trailing"})
+	if len(attrs) != 2 {
+		t.Fatalf("got %d attrs, want 2 (trailing odd key dropped): %+v", len(attrs), attrs)
+	}
+	if attrs[0].Key != "path" || attrs[0].Value != "/healthz" {
+		t.Errorf("unexpected first attr: %+v", attrs[0])
+	}
+	if attrs[1].Key != "status" || attrs[1].Value != 200 {
+		t.Errorf("unexpected second attr: %+v", attrs[1])
+	}
+}
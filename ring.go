@@ -0,0 +1,124 @@
+package bivalve
+
+import (
+	"sync"
+	"time"
+)
+
+// RingBufferHandler keeps the last N Records in memory and lets
+// callers snapshot or stream them -- the backing store for DebugHandler,
+// so an operator can attach to a running service and watch logs without
+// shelling into the host.
+type RingBufferHandler struct {
+	mu   sync.Mutex
+	buf  []Record
+	next int
+	full bool
+
+	subsMu sync.Mutex
+	subs   map[chan Record]struct{}
+}
+
+// NewRingBufferHandler returns a RingBufferHandler retaining the last
+// size Records. A size <= 0 is treated as 1.
+func NewRingBufferHandler(size int) *RingBufferHandler {
+	if size <= 0 {
+		size = 1
+	}
+	return &RingBufferHandler{
+		buf:  make([]Record, size),
+		subs: make(map[chan Record]struct{}),
+	}
+}
+
+// Handle stores r and publishes it to any live subscribers.
+func (h *RingBufferHandler) Handle(r Record) error {
+	h.mu.Lock()
+	h.buf[h.next] = r
+	h.next = (h.next + 1) % len(h.buf)
+	if h.next == 0 {
+		h.full = true
+	}
+	h.mu.Unlock()
+
+	h.publish(r)
+	return nil
+}
+
+// Snapshot returns up to the last n retained Records, oldest first. n <=
+// 0 means "everything retained".
+func (h *RingBufferHandler) Snapshot(n int) []Record {
+	h.mu.Lock()
+	all := h.orderedLocked()
+	h.mu.Unlock()
+
+	if n <= 0 || n >= len(all) {
+		return all
+	}
+	return all[len(all)-n:]
+}
+
+// Since returns retained Records timestamped after t, oldest first.
+func (h *RingBufferHandler) Since(t time.Time) []Record {
+	h.mu.Lock()
+	all := h.orderedLocked()
+	h.mu.Unlock()
+
+	i := 0
+	for ; i < len(all); i++ {
+		if all[i].Time.After(t) {
+			break
+		}
+	}
+	return all[i:]
+}
+
+// orderedLocked returns the retained Records oldest-first. Caller must
+// hold h.mu.
+func (h *RingBufferHandler) orderedLocked() []Record {
+	if !h.full {
+		out := make([]Record, h.next)
+		copy(out, h.buf[:h.next])
+		return out
+	}
+	out := make([]Record, len(h.buf))
+	copy(out, h.buf[h.next:])
+	copy(out[len(h.buf)-h.next:], h.buf[:h.next])
+	return out
+}
+
+// Subscribe returns a channel that receives every Record handled from
+// this point on, and a cancel func that stops the subscription and
+// closes the channel. Subscribers that fall behind are dropped rather
+// than blocking Handle.
+func (h *RingBufferHandler) Subscribe() (<-chan Record, func()) {
+	ch := make(chan Record, 256)
+
+	h.subsMu.Lock()
+	h.subs[ch] = struct{}{}
+	h.subsMu.Unlock()
+
+	cancel := func() {
+		h.subsMu.Lock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+		h.subsMu.Unlock()
+	}
+	return ch, cancel
+}
+
+func (h *RingBufferHandler) publish(r Record) {
+	h.subsMu.Lock()
+	defer h.subsMu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- r:
+		default:
+			// subscriber fell behind; drop rather than block Handle.
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
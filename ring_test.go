@@ -0,0 +1,99 @@
+package bivalve
+
+import (
+	"testing"
+	"time"
+)
+
+func recordAt(msg string, t time.Time) Record {
+	return Record{Time: t, Level: LevelInfo, Message: msg}
+}
+
+func TestRingBufferHandlerSnapshotWrapsAround(t *testing.T) {
+	h := NewRingBufferHandler(3)
+	base := time.Now()
+	for i, msg := range []string{"a", "b", "c", "d"} {
+		h.Handle(recordAt(msg, base.Add(time.Duration(i)*time.Second)))
+	}
+
+	got := h.Snapshot(0)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 retained records after wraparound, got %d", len(got))
+	}
+	want := []string{"b", "c", "d"}
+	for i, r := range got {
+		if r.Message != want[i] {
+			t.Errorf("record %d = %q, want %q", i, r.Message, want[i])
+		}
+	}
+}
+
+func TestRingBufferHandlerSnapshotN(t *testing.T) {
+	h := NewRingBufferHandler(10)
+	base := time.Now()
+	for i, msg := range []string{"a", "b", "c"} {
+		h.Handle(recordAt(msg, base.Add(time.Duration(i)*time.Second)))
+	}
+
+	got := h.Snapshot(2)
+	if len(got) != 2 || got[0].Message != "b" || got[1].Message != "c" {
+		t.Errorf("Snapshot(2) = %+v, want last 2 records [b c]", got)
+	}
+}
+
+func TestRingBufferHandlerSince(t *testing.T) {
+	h := NewRingBufferHandler(10)
+	base := time.Now()
+	h.Handle(recordAt("a", base))
+	h.Handle(recordAt("b", base.Add(time.Second)))
+	h.Handle(recordAt("c", base.Add(2*time.Second)))
+
+	got := h.Since(base)
+	if len(got) != 2 || got[0].Message != "b" || got[1].Message != "c" {
+		t.Errorf("Since(base) = %+v, want [b c]", got)
+	}
+}
+
+func TestRingBufferHandlerSubscribeReceivesAndCancelCloses(t *testing.T) {
+	h := NewRingBufferHandler(10)
+	ch, cancel := h.Subscribe()
+
+	h.Handle(recordAt("hello", time.Now()))
+
+	select {
+	case r := <-ch:
+		if r.Message != "hello" {
+			t.Errorf("got message %q, want hello", r.Message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for subscribed record")
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to be closed after cancel")
+	}
+}
+
+func TestRingBufferHandlerDropsOnBackpressure(t *testing.T) {
+	h := NewRingBufferHandler(10)
+	ch, cancel := h.Subscribe()
+	defer cancel()
+
+	// Flood well past the subscriber channel's buffer without ever
+	// reading it; Handle must not block.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			h.Handle(recordAt("flood", time.Now()))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handle blocked on a slow subscriber instead of dropping")
+	}
+	<-ch // records buffered before the subscriber was dropped are still delivered
+}
@@ -0,0 +1,161 @@
+package bivalve
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFileWriter is an io.WriteCloser that rotates its underlying
+// file once it exceeds MaxSizeMB, keeping at most MaxBackups rotated
+// files (gzip-compressed when Compress is set) and pruning anything
+// older than MaxAgeDays. A zero MaxSizeMB/MaxAgeDays/MaxBackups disables
+// that particular policy, so a RotatingFileWriter with everything unset
+// behaves like a plain append-only file.
+type RotatingFileWriter struct {
+	Filename   string
+	MaxSizeMB  int
+	MaxAgeDays int
+	MaxBackups int
+	Compress   bool
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewRotatingFileWriter returns a RotatingFileWriter for filename with no
+// rotation policy configured; set its fields before first Write.
+func NewRotatingFileWriter(filename string) *RotatingFileWriter {
+	return &RotatingFileWriter{Filename: filename}
+}
+
+// Write appends p to the current file, rotating first if it would push
+// the file past MaxSizeMB.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.MaxSizeMB > 0 && w.size+int64(len(p)) > int64(w.MaxSizeMB)*1024*1024 {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+func (w *RotatingFileWriter) open() error {
+	f, err := os.OpenFile(w.Filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = fi.Size()
+	return nil
+}
+
+// rotate closes the current file, renames it to a timestamped backup
+// (compressing it if requested), prunes old backups in the background,
+// and opens a fresh file in Filename's place.
+func (w *RotatingFileWriter) rotate() error {
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+
+	backup := fmt.Sprintf("%s.%s", w.Filename, time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(w.Filename, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if w.Compress {
+		if err := gzipFile(backup); err == nil {
+			os.Remove(backup)
+		}
+	}
+	go w.prune()
+
+	return w.open()
+}
+
+// prune removes rotated backups of Filename beyond MaxBackups or older
+// than MaxAgeDays. It runs in its own goroutine off the write path.
+func (w *RotatingFileWriter) prune() {
+	dir := filepath.Dir(w.Filename)
+	prefix := filepath.Base(w.Filename) + "."
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var backups []os.FileInfo
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			backups = append(backups, info)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime().After(backups[j].ModTime())
+	})
+
+	cutoff := time.Now().AddDate(0, 0, -w.MaxAgeDays)
+	for i, info := range backups {
+		expired := w.MaxAgeDays > 0 && info.ModTime().Before(cutoff)
+		excess := w.MaxBackups > 0 && i >= w.MaxBackups
+		if expired || excess {
+			os.Remove(filepath.Join(dir, info.Name()))
+		}
+	}
+}
+
+// gzipFile compresses name to name+".gz".
+func gzipFile(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(name + ".gz")
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		gz.Close()
+		return err
+	}
+	return gz.Close()
+}
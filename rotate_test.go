@@ -0,0 +1,130 @@
+package bivalve
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileWriterRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w := NewRotatingFileWriter(path)
+	defer w.Close()
+
+	w.MaxSizeMB = 1
+	payload := make([]byte, 1024*1024+1)
+	if _, err := w.Write(payload); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var backups int
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "app.log.") {
+			backups++
+		}
+	}
+	if backups != 1 {
+		t.Errorf("expected exactly one rotated backup, found %d entries in %v", backups, entries)
+	}
+}
+
+func TestRotatingFileWriterCompress(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w := NewRotatingFileWriter(path)
+	w.MaxSizeMB = 1
+	w.Compress = true
+	defer w.Close()
+
+	payload := make([]byte, 1024*1024+1)
+	if _, err := w.Write(payload); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	w.prune() // prune() is normally backgrounded by rotate(); call it synchronously here
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gz, uncompressed int
+	for _, e := range entries {
+		switch {
+		case strings.HasSuffix(e.Name(), ".gz"):
+			gz++
+		case strings.HasPrefix(e.Name(), "app.log.") && !strings.HasSuffix(e.Name(), ".gz"):
+			uncompressed++
+		}
+	}
+	if gz != 1 {
+		t.Errorf("expected one .gz backup, found %d (entries: %v)", gz, entries)
+	}
+	if uncompressed != 0 {
+		t.Errorf("expected the uncompressed backup to be removed, found %d", uncompressed)
+	}
+}
+
+func TestRotatingFileWriterPruneByMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	for i := 0; i < 5; i++ {
+		name := filepath.Join(dir, "app.log."+time.Now().UTC().Format("20060102T150405.000000000"))
+		if err := os.WriteFile(name, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Ensure distinct, increasing mtimes so ordering by recency is stable.
+		mtime := time.Now().Add(time.Duration(i) * time.Second)
+		if err := os.Chtimes(name, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := &RotatingFileWriter{Filename: path, MaxBackups: 2}
+	w.prune()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected 2 backups to survive MaxBackups=2, got %d: %v", len(entries), entries)
+	}
+}
+
+func TestRotatingFileWriterPruneByMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	oldBackup := filepath.Join(dir, "app.log.old")
+	if err := os.WriteFile(oldBackup, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().AddDate(0, 0, -10)
+	if err := os.Chtimes(oldBackup, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	freshBackup := filepath.Join(dir, "app.log.fresh")
+	if err := os.WriteFile(freshBackup, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &RotatingFileWriter{Filename: path, MaxAgeDays: 1}
+	w.prune()
+
+	if _, err := os.Stat(oldBackup); !os.IsNotExist(err) {
+		t.Errorf("expected expired backup to be pruned, stat err = %v", err)
+	}
+	if _, err := os.Stat(freshBackup); err != nil {
+		t.Errorf("expected fresh backup to survive, stat err = %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !windows
+
+package bivalve
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchSIGUSR1 installs a signal handler that cycles the verbosity
+// level error -> info -> debug -> error each time the process receives
+// SIGUSR1, so an operator can ratchet debug logging up (and back down)
+// on a running service without a restart. It returns a stop func that
+// removes the handler.
+func WatchSIGUSR1() (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-sigs:
+				cycleLevel()
+			case <-done:
+				signal.Stop(sigs)
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// cycleLevel advances the package's verbosity one step in the cycle
+// error -> info -> debug -> error. It CAS-loops on the raw level rather
+// than doing GetLevel-then-SetLevel, so a SIGUSR1 racing a concurrent
+// LevelHandler PUT can't silently clobber it.
+func cycleLevel() {
+	for {
+		cur := level.Load()
+		var next int32
+		switch int8(cur) {
+		case errorLevel:
+			next = infoLevel
+		case infoLevel:
+			next = debugLevel
+		default:
+			next = errorLevel
+		}
+		if level.CompareAndSwap(cur, next) {
+			resetVCache()
+			return
+		}
+	}
+}
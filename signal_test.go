@@ -0,0 +1,63 @@
+//go:build !windows
+
+package bivalve
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCycleLevelAdvancesErrorInfoDebug(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+
+	level.Store(errorLevel)
+	cycleLevel()
+	if GetLevel() != "info" {
+		t.Fatalf("after first cycle, GetLevel() = %q, want info", GetLevel())
+	}
+	cycleLevel()
+	if GetLevel() != "debug" {
+		t.Fatalf("after second cycle, GetLevel() = %q, want debug", GetLevel())
+	}
+	cycleLevel()
+	if GetLevel() != "error" {
+		t.Fatalf("after third cycle, GetLevel() = %q, want error (wraps around)", GetLevel())
+	}
+}
+
+func TestWatchSIGUSR1ReturnsAWorkingStop(t *testing.T) {
+	stop := WatchSIGUSR1()
+	stop()
+}
+
+// TestCycleLevelConcurrentWithVDepth reproduces the vcache race/panic
+// flagged in review: cycleLevel resets the V(n) memoization cache on
+// every CAS success, and that reset must not race with VDepth's
+// concurrent Load/Store of the same cache. Run with -race.
+func TestCycleLevelConcurrentWithVDepth(t *testing.T) {
+	orig := level.Load()
+	defer level.Store(orig)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				V(1)
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		cycleLevel()
+	}
+	close(stop)
+	wg.Wait()
+}
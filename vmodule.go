@@ -0,0 +1,151 @@
+package bivalve
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Verbose is the result of a V(n) check: a bool that gained Info/Infof
+// methods so callers can write V(2).Infof("...") the way glog does.
+type Verbose bool
+
+// vmoduleRule is one "pattern=level" entry parsed from a vmodule spec.
+type vmoduleRule struct {
+	pattern string
+	level   int32
+}
+
+var (
+	vmoduleMu    sync.RWMutex
+	vmoduleRules []vmoduleRule
+
+	// vcache memoizes the V(n) decision for a given (call site, level) so
+	// repeated calls at the same log statement are O(1) after the first.
+	// It's a plain map guarded by vcacheMu rather than a sync.Map because
+	// it needs to be cleared in place -- reassigning a sync.Map out from
+	// under concurrent Load/Store callers is itself a race (and a nil-map
+	// panic waiting to happen), so resetVCache locks and deletes instead.
+	vcacheMu sync.Mutex
+	vcache   map[vcacheKey]bool
+)
+
+type vcacheKey struct {
+	pc    uintptr
+	level int32
+}
+
+// resetVCache discards every memoized V(n) decision. Called whenever the
+// global level or vmodule rules change, since either can flip a
+// previously-cached decision.
+func resetVCache() {
+	vcacheMu.Lock()
+	clear(vcache)
+	vcacheMu.Unlock()
+}
+
+// SetVModule parses a vmodule spec such as
+// "bivalve/*=4,http/handlers.go=2" and installs it as the active set of
+// per-file verbosity overrides. An empty spec clears all overrides.
+func SetVModule(spec string) error {
+	var rules []vmoduleRule
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("bivalve: invalid vmodule entry %q", part)
+		}
+		lvl, err := strconv.ParseInt(strings.TrimSpace(kv[1]), 10, 32)
+		if err != nil {
+			return fmt.Errorf("bivalve: invalid vmodule level in %q: %w", part, err)
+		}
+		rules = append(rules, vmoduleRule{pattern: strings.TrimSpace(kv[0]), level: int32(lvl)})
+	}
+
+	vmoduleMu.Lock()
+	vmoduleRules = rules
+	vmoduleMu.Unlock()
+	resetVCache()
+	return nil
+}
+
+// moduleLevel returns the verbosity threshold that applies to file,
+// falling back to the package's global level when no vmodule pattern
+// matches it. A pattern is matched against the trailing path segments of
+// file equal in number to the pattern's own segment count, so
+// "http/handlers.go" matches ".../http/handlers.go" and "bivalve/*"
+// matches any file directly inside a "bivalve" directory -- not just a
+// bare basename, which is the only form a single path.Match against
+// filepath.Base(file) could ever satisfy.
+func moduleLevel(file string) int32 {
+	vmoduleMu.RLock()
+	defer vmoduleMu.RUnlock()
+
+	segments := strings.Split(filepath.ToSlash(file), "/")
+	for _, rule := range vmoduleRules {
+		depth := strings.Count(rule.pattern, "/") + 1
+		if depth > len(segments) {
+			continue
+		}
+		candidate := strings.Join(segments[len(segments)-depth:], "/")
+		if matched, _ := path.Match(rule.pattern, candidate); matched {
+			return rule.level
+		}
+	}
+	return int32(currentLevel())
+}
+
+// V reports whether verbosity level n is enabled for the caller's file,
+// per the active vmodule rules. Typical use: V(2).Infof("retrying %s", id).
+func V(n int32) Verbose {
+	return VDepth(1, n)
+}
+
+// VDepth is V, but the caller's file is found depth frames above VDepth's
+// own caller (depth 0 is that caller itself) -- for helpers that want to report V(n) checks as if they were
+// made by their own caller rather than by the helper.
+func VDepth(depth int, n int32) Verbose {
+	pc, file, _, ok := runtime.Caller(1 + depth)
+	if !ok {
+		return Verbose(n <= int32(currentLevel()))
+	}
+	key := vcacheKey{pc: pc, level: n}
+	vcacheMu.Lock()
+	if v, ok := vcache[key]; ok {
+		vcacheMu.Unlock()
+		return Verbose(v)
+	}
+	vcacheMu.Unlock()
+
+	enabled := n <= moduleLevel(file)
+
+	vcacheMu.Lock()
+	if vcache == nil {
+		vcache = make(map[vcacheKey]bool)
+	}
+	vcache[key] = enabled
+	vcacheMu.Unlock()
+
+	return Verbose(enabled)
+}
+
+// Info logs s at info level if v is true.
+func (v Verbose) Info(s string) {
+	if v {
+		defaultLogger.emit(3, LevelInfo, s, nil)
+	}
+}
+
+// Infof logs a formatted message at info level if v is true.
+func (v Verbose) Infof(format string, args ...interface{}) {
+	if v {
+		defaultLogger.emit(3, LevelInfo, fmt.Sprintf(format, args...), nil)
+	}
+}
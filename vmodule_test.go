@@ -0,0 +1,90 @@
+package bivalve
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSetVModuleParsesEntries(t *testing.T) {
+	defer SetVModule("")
+	if err := SetVModule("bivalve/*=4, http/handlers.go=2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := moduleLevel("/src/bivalve/foo.go"); got != 4 {
+		t.Errorf("moduleLevel(bivalve/foo.go) = %d, want 4", got)
+	}
+	if got := moduleLevel("/src/http/handlers.go"); got != 2 {
+		t.Errorf("moduleLevel(http/handlers.go) = %d, want 2", got)
+	}
+}
+
+func TestSetVModuleRejectsMalformedEntries(t *testing.T) {
+	defer SetVModule("")
+	if err := SetVModule("no-equals-sign"); err == nil {
+		t.Error("expected an error for an entry without '='")
+	}
+	if err := SetVModule("foo.go=notanumber"); err == nil {
+		t.Error("expected an error for a non-numeric level")
+	}
+}
+
+func TestModuleLevelFallsBackToGlobalLevel(t *testing.T) {
+	defer SetVModule("")
+	origLevel := level.Load()
+	defer level.Store(origLevel)
+
+	SetVModule("")
+	level.Store(debugLevel)
+	if got := moduleLevel("/src/unrelated/file.go"); got != debugLevel {
+		t.Errorf("moduleLevel with no rules = %d, want global level %d", got, debugLevel)
+	}
+}
+
+func TestVDepthReturnsVerboseAndCaches(t *testing.T) {
+	defer SetVModule("")
+	if err := SetVModule("vmodule_test.go=4"); err != nil {
+		t.Fatal(err)
+	}
+	if v := V(4); !bool(v) {
+		t.Error("V(4) should be enabled for this file at vmodule level 4")
+	}
+	if v := V(8); bool(v) {
+		t.Error("V(8) should not be enabled for this file at vmodule level 4")
+	}
+	vcacheMu.Lock()
+	cached := len(vcache)
+	vcacheMu.Unlock()
+	if cached == 0 {
+		t.Error("expected V(n) decisions to be memoized in vcache")
+	}
+}
+
+// TestResetVCacheUnderConcurrentVDepth reproduces the vcache race/panic
+// flagged in review: resetVCache must clear the memoization cache in
+// place rather than reassigning the variable out from under concurrent
+// VDepth callers. Run with -race.
+func TestResetVCacheUnderConcurrentVDepth(t *testing.T) {
+	defer SetVModule("")
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				V(1)
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		SetVModule("vmodule_test.go=4")
+	}
+	close(stop)
+	wg.Wait()
+}